@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/launchdarkly/go-sdk-common/v3/ldcontext"
+)
+
+// makeContext returns the context used for flag evaluation
+// APP_CONTEXT_JSON (inline) or APP_CONTEXT_FILE (path to a file) supply a context in the LaunchDarkly JSON format,
+// which may be a single or multi-context. Otherwise the default demo context is used, with its kind set by
+// APP_CONTEXT_KIND, or a multi-context is built from the kind:key pairs in APP_CONTEXT_MULTI.
+// If none of those are set, APP_CONTEXT_FROM_ENV=true builds a context from the container's metadata instead.
+func makeContext() (ldcontext.Context, error) {
+	contextJSON := os.Getenv("APP_CONTEXT_JSON")
+	contextFile := os.Getenv("APP_CONTEXT_FILE")
+	contextKind := os.Getenv("APP_CONTEXT_KIND")
+	contextMulti := os.Getenv("APP_CONTEXT_MULTI")
+
+	switch {
+	case contextJSON != "" && contextFile != "":
+		return ldcontext.Context{}, errors.New("set only one of APP_CONTEXT_JSON and APP_CONTEXT_FILE")
+	case (contextJSON != "" || contextFile != "") && (contextKind != "" || contextMulti != ""):
+		return ldcontext.Context{}, errors.New(
+			"APP_CONTEXT_KIND and APP_CONTEXT_MULTI cannot be combined with APP_CONTEXT_JSON or APP_CONTEXT_FILE")
+	case contextKind != "" && contextMulti != "":
+		return ldcontext.Context{}, errors.New("set only one of APP_CONTEXT_KIND and APP_CONTEXT_MULTI")
+	case contextJSON != "":
+		return parseContext([]byte(contextJSON), "APP_CONTEXT_JSON")
+	case contextFile != "":
+		data, err := os.ReadFile(contextFile)
+		if err != nil {
+			return ldcontext.Context{}, fmt.Errorf("reading APP_CONTEXT_FILE: %w", err)
+		}
+		return parseContext(data, contextFile)
+	case contextMulti != "":
+		return makeMultiContext(contextMulti)
+	case contextKind == "" && os.Getenv("APP_CONTEXT_FROM_ENV") == "true":
+		return makeEnvContext()
+	}
+
+	// NOTE: The dev-server does not serve targeting rules
+	builder := ldcontext.NewBuilder("context-key-123abc").
+		Name("Sandy")
+	if contextKind != "" {
+		builder.Kind(ldcontext.Kind(contextKind))
+	}
+
+	c := builder.Build()
+	if err := c.Err(); err != nil {
+		return ldcontext.Context{}, fmt.Errorf("invalid APP_CONTEXT_KIND %q: %w", contextKind, err)
+	}
+	return c, nil
+}
+
+// makeMultiContext builds a multi-context from a comma-separated list of kind:key pairs
+// such as user:user-key-123,org:org-key-456. Each kind may only appear once.
+func makeMultiContext(value string) (ldcontext.Context, error) {
+	multi := ldcontext.NewMultiBuilder()
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		kind, key, found := strings.Cut(entry, ":")
+		if !found || kind == "" || key == "" {
+			return ldcontext.Context{}, fmt.Errorf("invalid APP_CONTEXT_MULTI entry %q, expected kind:key", entry)
+		}
+
+		c := ldcontext.NewWithKind(ldcontext.Kind(kind), key)
+		if err := c.Err(); err != nil {
+			return ldcontext.Context{}, fmt.Errorf("invalid APP_CONTEXT_MULTI kind %q: %w", kind, err)
+		}
+		multi.Add(c)
+	}
+
+	c := multi.Build()
+	if err := c.Err(); err != nil {
+		return ldcontext.Context{}, fmt.Errorf("invalid APP_CONTEXT_MULTI: %w", err)
+	}
+	return c, nil
+}
+
+// envContextKind is the kind of the context built by makeEnvContext
+const envContextKind = "container"
+
+// envContextAttributes maps the container metadata variables read by makeEnvContext to context attributes
+// unset variables are left out of the context
+var envContextAttributes = []struct {
+	envVar    string
+	attribute string
+}{
+	{"HOSTNAME", "hostname"},
+	{"POD_NAME", "podName"},
+	{"POD_NAMESPACE", "namespace"},
+	{"NODE_NAME", "nodeName"},
+	{"REGION", "region"},
+}
+
+// makeEnvContext builds a container context from the variables in envContextAttributes
+// the key is POD_NAME, falling back to HOSTNAME, which Docker sets to the container ID by default
+func makeEnvContext() (ldcontext.Context, error) {
+	key := os.Getenv("POD_NAME")
+	if key == "" {
+		key = os.Getenv("HOSTNAME")
+	}
+	if key == "" {
+		return ldcontext.Context{}, errors.New("APP_CONTEXT_FROM_ENV=true requires POD_NAME or HOSTNAME to be set")
+	}
+
+	builder := ldcontext.NewBuilder(key).Kind(envContextKind)
+	for _, attr := range envContextAttributes {
+		if value := os.Getenv(attr.envVar); value != "" {
+			builder.SetString(attr.attribute, value)
+		}
+	}
+
+	c := builder.Build()
+	if err := c.Err(); err != nil {
+		return ldcontext.Context{}, fmt.Errorf("invalid context from APP_CONTEXT_FROM_ENV: %w", err)
+	}
+	return c, nil
+}
+
+// parseContext unmarshals a context with ldcontext's own JSON rules and checks that it is valid
+// source names where the JSON came from, for error messages
+func parseContext(data []byte, source string) (ldcontext.Context, error) {
+	var c ldcontext.Context
+	if err := json.Unmarshal(data, &c); err != nil {
+		return ldcontext.Context{}, fmt.Errorf("malformed context in %s: %w", source, err)
+	}
+	if err := c.Err(); err != nil {
+		return ldcontext.Context{}, fmt.Errorf("invalid context in %s: %w", source, err)
+	}
+	return c, nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/launchdarkly/go-sdk-common/v3/ldcontext"
+)
+
+// clearContextEnv unsets the variables read by makeContext for the duration of the test
+func clearContextEnv(t *testing.T) {
+	for _, name := range []string{"APP_CONTEXT_JSON", "APP_CONTEXT_FILE", "APP_CONTEXT_KIND", "APP_CONTEXT_MULTI",
+		"APP_CONTEXT_FROM_ENV"} {
+		t.Setenv(name, "")
+	}
+	for _, attr := range envContextAttributes {
+		t.Setenv(attr.envVar, "")
+	}
+}
+
+func TestMakeContextFromEnv(t *testing.T) {
+	tests := []struct {
+		name      string
+		env       map[string]string
+		wantKind  ldcontext.Kind
+		wantKey   string
+		wantAttrs map[string]string
+		wantErr   bool
+	}{
+		{
+			name:      "hostname only",
+			env:       map[string]string{"HOSTNAME": "abc123"},
+			wantKind:  envContextKind,
+			wantKey:   "abc123",
+			wantAttrs: map[string]string{"hostname": "abc123"},
+		},
+		{
+			name: "pod name takes priority over hostname",
+			env: map[string]string{"HOSTNAME": "abc123", "POD_NAME": "web-1", "POD_NAMESPACE": "dev",
+				"NODE_NAME": "node-1", "REGION": "eu-west-1"},
+			wantKind: envContextKind,
+			wantKey:  "web-1",
+			wantAttrs: map[string]string{"hostname": "abc123", "podName": "web-1", "namespace": "dev",
+				"nodeName": "node-1", "region": "eu-west-1"},
+		},
+		{
+			name:    "no pod name or hostname",
+			env:     map[string]string{"REGION": "eu-west-1"},
+			wantErr: true,
+		},
+		{
+			name:     "APP_CONTEXT_JSON takes precedence",
+			env:      map[string]string{"HOSTNAME": "abc123", "APP_CONTEXT_JSON": `{"kind": "user", "key": "json-key"}`},
+			wantKind: ldcontext.DefaultKind,
+			wantKey:  "json-key",
+		},
+		{
+			name:     "APP_CONTEXT_KIND takes precedence",
+			env:      map[string]string{"HOSTNAME": "abc123", "APP_CONTEXT_KIND": "org"},
+			wantKind: "org",
+			wantKey:  "context-key-123abc",
+		},
+		{
+			name:     "APP_CONTEXT_MULTI takes precedence",
+			env:      map[string]string{"HOSTNAME": "abc123", "APP_CONTEXT_MULTI": "user:u1,org:o1"},
+			wantKind: ldcontext.MultiKind,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearContextEnv(t)
+			t.Setenv("APP_CONTEXT_FROM_ENV", "true")
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			c, err := makeContext()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", c.JSONString())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.Kind() != tt.wantKind {
+				t.Errorf("kind is %q, want %q", c.Kind(), tt.wantKind)
+			}
+			if tt.wantKey != "" && c.Key() != tt.wantKey {
+				t.Errorf("key is %q, want %q", c.Key(), tt.wantKey)
+			}
+			for attr, want := range tt.wantAttrs {
+				if got := c.GetValue(attr).StringValue(); got != want {
+					t.Errorf("attribute %s is %q, want %q", attr, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMakeContextFromEnvDisabled(t *testing.T) {
+	clearContextEnv(t)
+	t.Setenv("HOSTNAME", "abc123")
+
+	c, err := makeContext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Key() != "context-key-123abc" {
+		t.Errorf("expected the default context without APP_CONTEXT_FROM_ENV, got %s", c.JSONString())
+	}
+}
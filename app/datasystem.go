@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/launchdarkly/go-server-sdk/v7/ldcomponents"
+	"github.com/launchdarkly/go-server-sdk/v7/subsystems"
+)
+
+// dataSourceTiming holds the APP_POLL_INTERVAL and APP_RECONNECT_DELAY overrides
+// a zero value means the variable is unset and the SDK default applies
+type dataSourceTiming struct {
+	pollInterval   time.Duration
+	reconnectDelay time.Duration
+}
+
+// readDataSourceTiming parses APP_POLL_INTERVAL and APP_RECONNECT_DELAY, which are Go duration strings
+// a poll interval below the SDK minimum is raised to it, with a warning
+func readDataSourceTiming() (dataSourceTiming, error) {
+	pollInterval, err := durationEnv("APP_POLL_INTERVAL")
+	if err != nil {
+		return dataSourceTiming{}, err
+	}
+	if pollInterval > 0 && pollInterval < ldcomponents.DefaultPollInterval {
+		fmt.Printf("WARNING: APP_POLL_INTERVAL %s is below the SDK minimum, using %s\n",
+			pollInterval, ldcomponents.DefaultPollInterval)
+		pollInterval = ldcomponents.DefaultPollInterval
+	}
+
+	reconnectDelay, err := durationEnv("APP_RECONNECT_DELAY")
+	if err != nil {
+		return dataSourceTiming{}, err
+	}
+	return dataSourceTiming{pollInterval: pollInterval, reconnectDelay: reconnectDelay}, nil
+}
+
+// durationEnv parses the Go duration string in the named variable, which must be greater than zero if set
+// zero means the variable is unset
+func durationEnv(name string) (time.Duration, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be greater than zero", name, value)
+	}
+	return d, nil
+}
+
+// makeDataSystem returns the data system selected by APP_DATA_SYSTEM: default, streaming, polling or daemon
+// if baseUri is set, the streaming and polling synchronizers connect to it instead of LaunchDarkly
+// the SDK's mode builders are used unless timing overrides a synchronizer they would create
+// a nil result leaves the SDK's standard data source configuration in place
+func makeDataSystem(baseUri string,
+	timing dataSourceTiming) (subsystems.ComponentConfigurer[subsystems.DataSystemConfiguration], error) {
+	mode := os.Getenv("APP_DATA_SYSTEM")
+	if mode == "" {
+		return nil, nil
+	}
+
+	modes := ldcomponents.DataSystem()
+	if baseUri != "" {
+		modes = modes.WithRelayProxyEndpoints(baseUri)
+	}
+
+	switch mode {
+	case "default":
+		if timing == (dataSourceTiming{}) {
+			return modes.Default(), nil
+		}
+		// same as Default(), which doesn't expose its synchronizer builders
+		polling := timing.pollingV2(baseUri)
+		return modes.Custom().Initializers(polling.AsInitializer()).
+			Synchronizers(timing.streamingV2(baseUri), polling), nil
+	case "streaming":
+		warnUnused(timing.pollInterval, "APP_POLL_INTERVAL", mode)
+		if timing.reconnectDelay == 0 {
+			return modes.Streaming(), nil
+		}
+		return modes.Custom().Synchronizers(timing.streamingV2(baseUri), nil), nil
+	case "polling":
+		warnUnused(timing.reconnectDelay, "APP_RECONNECT_DELAY", mode)
+		if timing.pollInterval == 0 {
+			return modes.Polling(), nil
+		}
+		return modes.Custom().Synchronizers(timing.pollingV2(baseUri), nil), nil
+	case "daemon":
+		// daemon mode only reads from a persistent store populated by Relay Proxy, so there has to be a store
+		// integration (e.g. Redis) to read from. The demo app does not bundle one.
+		return nil, errors.New("APP_DATA_SYSTEM=daemon requires a persistent store integration, " +
+			"which the demo app does not include")
+	default:
+		return nil, fmt.Errorf("unknown APP_DATA_SYSTEM %q, expected one of default, streaming, polling or daemon", mode)
+	}
+}
+
+// makeDataSource returns the standard data source when APP_DATA_SYSTEM is unset
+// it streams, so only APP_RECONNECT_DELAY applies. A nil result leaves the SDK default in place.
+func makeDataSource(timing dataSourceTiming) subsystems.ComponentConfigurer[subsystems.DataSource] {
+	warnUnused(timing.pollInterval, "APP_POLL_INTERVAL", "")
+	if timing.reconnectDelay == 0 {
+		return nil
+	}
+	return ldcomponents.StreamingDataSource().InitialReconnectDelay(timing.reconnectDelay)
+}
+
+// streamingV2 returns a streaming synchronizer with the reconnect delay override, if any
+func (t dataSourceTiming) streamingV2(baseUri string) *ldcomponents.StreamingDataSourceBuilderV2 {
+	streaming := ldcomponents.StreamingDataSourceV2()
+	if baseUri != "" {
+		streaming.BaseURI(baseUri)
+	}
+	if t.reconnectDelay > 0 {
+		streaming.InitialReconnectDelay(t.reconnectDelay)
+	}
+	return streaming
+}
+
+// pollingV2 returns a polling synchronizer with the poll interval override, if any
+func (t dataSourceTiming) pollingV2(baseUri string) *ldcomponents.PollingDataSourceBuilderV2 {
+	polling := ldcomponents.PollingDataSourceV2()
+	if baseUri != "" {
+		polling.BaseURI(baseUri)
+	}
+	if t.pollInterval > 0 {
+		polling.PollInterval(t.pollInterval)
+	}
+	return polling
+}
+
+// warnUnused prints a warning when an override is set that the selected data source doesn't use
+func warnUnused(value time.Duration, name, mode string) {
+	if value == 0 {
+		return
+	}
+	if mode == "" {
+		fmt.Printf("WARNING: %s is ignored because the standard data source streams; "+
+			"set APP_DATA_SYSTEM to default or polling to use it\n", name)
+		return
+	}
+	fmt.Printf("WARNING: %s is ignored with APP_DATA_SYSTEM=%s\n", name, mode)
+}
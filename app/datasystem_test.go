@@ -0,0 +1,135 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/launchdarkly/go-server-sdk/v7/ldcomponents"
+	"github.com/launchdarkly/go-server-sdk/v7/subsystems"
+)
+
+func TestDurationEnv(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "", want: 0},
+		{value: "45s", want: 45 * time.Second},
+		{value: "1m30s", want: 90 * time.Second},
+		{value: "100ms", want: 100 * time.Millisecond},
+		{value: "30", wantErr: true},
+		{value: "soon", wantErr: true},
+		{value: "0s", wantErr: true},
+		{value: "-1s", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("APP_TEST_DURATION", tt.value)
+			got, err := durationEnv("APP_TEST_DURATION")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadDataSourceTiming(t *testing.T) {
+	tests := []struct {
+		name           string
+		pollInterval   string
+		reconnectDelay string
+		want           dataSourceTiming
+		wantErr        bool
+	}{
+		{name: "unset"},
+		{name: "above minimum", pollInterval: "2m", want: dataSourceTiming{pollInterval: 2 * time.Minute}},
+		{name: "at minimum", pollInterval: "30s", want: dataSourceTiming{pollInterval: ldcomponents.DefaultPollInterval}},
+		{name: "below minimum", pollInterval: "1s", want: dataSourceTiming{pollInterval: ldcomponents.DefaultPollInterval}},
+		{name: "reconnect delay", reconnectDelay: "100ms", want: dataSourceTiming{reconnectDelay: 100 * time.Millisecond}},
+		{name: "invalid poll interval", pollInterval: "fast", wantErr: true},
+		{name: "invalid reconnect delay", reconnectDelay: "-1s", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("APP_POLL_INTERVAL", tt.pollInterval)
+			t.Setenv("APP_RECONNECT_DELAY", tt.reconnectDelay)
+			got, err := readDataSourceTiming()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataSourceTimingBuilders(t *testing.T) {
+	timing := dataSourceTiming{pollInterval: time.Minute, reconnectDelay: 250 * time.Millisecond}
+	clientContext := subsystems.BasicClientContext{}
+
+	streaming := timing.streamingV2("http://ld-dev-server:8765").DescribeConfiguration(clientContext)
+	if got := streaming.GetByKey("reconnectTimeMillis").IntValue(); got != 250 {
+		t.Errorf("reconnectTimeMillis is %d, want 250", got)
+	}
+	polling := timing.pollingV2("http://ld-dev-server:8765").DescribeConfiguration(clientContext)
+	if got := polling.GetByKey("pollingIntervalMillis").IntValue(); got != 60000 {
+		t.Errorf("pollingIntervalMillis is %d, want 60000", got)
+	}
+
+	defaults := dataSourceTiming{}.pollingV2("").DescribeConfiguration(clientContext)
+	want := int(ldcomponents.DefaultPollInterval.Milliseconds())
+	if got := defaults.GetByKey("pollingIntervalMillis").IntValue(); got != want {
+		t.Errorf("default pollingIntervalMillis is %d, want %d", got, want)
+	}
+}
+
+func TestMakeDataSystem(t *testing.T) {
+	overrides := dataSourceTiming{pollInterval: time.Minute, reconnectDelay: time.Second}
+	for _, mode := range []string{"default", "streaming", "polling"} {
+		for _, timing := range []dataSourceTiming{{}, overrides} {
+			t.Setenv("APP_DATA_SYSTEM", mode)
+			dataSystem, err := makeDataSystem("http://ld-dev-server:8765", timing)
+			if err != nil {
+				t.Fatalf("%s %+v: %v", mode, timing, err)
+			}
+			if dataSystem == nil {
+				t.Errorf("%s %+v: expected a data system", mode, timing)
+			}
+		}
+	}
+
+	for _, mode := range []string{"daemon", "unknown"} {
+		t.Setenv("APP_DATA_SYSTEM", mode)
+		if _, err := makeDataSystem("", dataSourceTiming{}); err == nil {
+			t.Errorf("%s: expected an error", mode)
+		}
+	}
+
+	t.Setenv("APP_DATA_SYSTEM", "")
+	if dataSystem, err := makeDataSystem("", overrides); err != nil || dataSystem != nil {
+		t.Errorf("unset APP_DATA_SYSTEM: got %v, %v", dataSystem, err)
+	}
+	if makeDataSource(dataSourceTiming{}) != nil {
+		t.Error("expected the SDK default data source without overrides")
+	}
+	if makeDataSource(overrides) == nil {
+		t.Error("expected a streaming data source with the reconnect delay")
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/launchdarkly/go-sdk-common/v3/ldcontext"
+	"github.com/launchdarkly/go-sdk-common/v3/ldreason"
+	ldclient "github.com/launchdarkly/go-server-sdk/v7"
+)
+
+// flagKeys returns the flags to evaluate from APP_FLAG_KEY, which may be a comma-separated list of keys
+// a list with no keys in it, such as ",", is an error
+func flagKeys() ([]string, error) {
+	value := os.Getenv("APP_FLAG_KEY")
+	if !strings.Contains(value, ",") {
+		return []string{value}, nil
+	}
+
+	var keys []string
+	for _, key := range strings.Split(value, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("APP_FLAG_KEY does not contain any flag keys")
+	}
+	return keys, nil
+}
+
+// evaluateFlags evaluates each flag against the context and prints the results
+// a single flag prints one line, several flags print a table of key/value/reason
+// it keeps going when a flag fails to evaluate and returns false if any of them did
+func evaluateFlags(client *ldclient.LDClient, keys []string, evalContext ldcontext.Context, withReason bool) bool {
+	if len(keys) == 1 {
+		var err error
+		if withReason {
+			err = printFlagDetail(client, keys[0], evalContext)
+		} else {
+			var result bool
+			result, err = client.BoolVariation(keys[0], evalContext, false)
+			if err == nil {
+				fmt.Printf("Flag Key [%s] result: [%v]\n", keys[0], result)
+			}
+		}
+		if err != nil {
+			fmt.Println("Error evaluating flag:", err)
+			return false
+		}
+		return true
+	}
+
+	ok := true
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "FLAG KEY\tVALUE\tREASON\tERROR")
+	for _, key := range keys {
+		result, detail, err := client.BoolVariationDetail(key, evalContext, false)
+		errText := ""
+		if err != nil {
+			errText = err.Error()
+			ok = false
+		}
+		fmt.Fprintf(table, "%s\t%v\t%s\t%s\n", key, result, detail.Reason, errText)
+	}
+	table.Flush()
+	return ok
+}
+
+// printFlagDetail evaluates the flag with BoolVariationDetail and prints the value, variation index and reason
+// if the reason is an error, such as a malformed flag or a wrong type, the error kind is printed as well
+func printFlagDetail(client *ldclient.LDClient, flagKey string, evalContext ldcontext.Context) error {
+	result, detail, err := client.BoolVariationDetail(flagKey, evalContext, false)
+
+	fmt.Printf("Flag Key [%s] result: [%v] variation: [%v] reason: [%s]\n",
+		flagKey, result, detail.VariationIndex.AsValue(), detail.Reason)
+
+	if detail.Reason.GetKind() == ldreason.EvalReasonError {
+		fmt.Printf("Flag Key [%s] evaluation error kind: [%s]\n", flagKey, detail.Reason.GetErrorKind())
+	}
+	return err
+}
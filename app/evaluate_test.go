@@ -0,0 +1,38 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFlagKeys(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{value: "my-flag", want: []string{"my-flag"}},
+		{value: "a, b ,c", want: []string{"a", "b", "c"}},
+		{value: "a,,b,", want: []string{"a", "b"}},
+		{value: ",", wantErr: true},
+		{value: " , ", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("APP_FLAG_KEY", tt.value)
+			keys, err := flagKeys()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got keys %q", keys)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(keys, tt.want) {
+				t.Errorf("got %q, want %q", keys, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ldclient "github.com/launchdarkly/go-server-sdk/v7"
+	"github.com/launchdarkly/go-server-sdk/v7/interfaces"
+	"github.com/launchdarkly/go-server-sdk/v7/ldcomponents"
+	"github.com/launchdarkly/go-server-sdk/v7/subsystems"
+)
+
+// buildHTTPConfig builds the configuration makeHTTPConfig returns, or the SDK default when it returns nil
+func buildHTTPConfig(t *testing.T) subsystems.HTTPConfiguration {
+	t.Helper()
+	configurer, err := makeHTTPConfig()
+	if err != nil {
+		t.Fatalf("makeHTTPConfig: %v", err)
+	}
+	if configurer == nil {
+		configurer = ldcomponents.HTTPConfiguration()
+	}
+	config, err := configurer.Build(subsystems.BasicClientContext{})
+	if err != nil {
+		t.Fatalf("building HTTP configuration: %v", err)
+	}
+	return config
+}
+
+// clearHTTPEnv unsets the variables read by makeHTTPConfig for the duration of the test
+func clearHTTPEnv(t *testing.T) {
+	t.Setenv("APP_CA_CERT", "")
+	t.Setenv("APP_TLS_INSECURE", "")
+	t.Setenv("APP_INSTANCE_ID", "")
+}
+
+func TestMakeHTTPConfigTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		caCert   string
+		insecure string
+		wantErr  bool
+	}{
+		{name: "untrusted certificate", wantErr: true},
+		{name: "APP_CA_CERT", caCert: caFile},
+		{name: "APP_TLS_INSECURE", insecure: "true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearHTTPEnv(t)
+			t.Setenv("APP_CA_CERT", tt.caCert)
+			t.Setenv("APP_TLS_INSECURE", tt.insecure)
+
+			resp, err := buildHTTPConfig(t).CreateHTTPClient().Get(server.URL)
+			if tt.wantErr {
+				if err == nil {
+					resp.Body.Close()
+					t.Fatal("expected the request to fail certificate verification")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+		})
+	}
+}
+
+func TestValidateHeaderValue(t *testing.T) {
+	for _, value := range []string{"app-1", "app 1", "app\t1"} {
+		if err := validateHeaderValue(value); err != nil {
+			t.Errorf("%q: unexpected error %v", value, err)
+		}
+	}
+	for _, value := range []string{"app\r\nX-Other: 1", "app\n1", "app\r1", "app\x7f"} {
+		if err := validateHeaderValue(value); err == nil {
+			t.Errorf("%q: expected an error", value)
+		}
+	}
+}
+
+func TestMakeHTTPConfigInstanceHeader(t *testing.T) {
+	clearHTTPEnv(t)
+	t.Setenv("APP_INSTANCE_ID", "app-1")
+
+	if got := buildHTTPConfig(t).DefaultHeaders.Get(instanceHeader); got != "app-1" {
+		t.Errorf("%s header is %q, want %q", instanceHeader, got, "app-1")
+	}
+}
+
+func TestMakeHTTPConfigRejectsInvalidInstanceID(t *testing.T) {
+	clearHTTPEnv(t)
+	t.Setenv("APP_INSTANCE_ID", "app-1\r\nX-Other: 1")
+
+	if _, err := makeHTTPConfig(); err == nil {
+		t.Error("expected an error for an instance ID containing CR/LF")
+	}
+}
+
+func TestInstanceHeaderOnSDKRequests(t *testing.T) {
+	tests := []struct {
+		name       string
+		dataSystem string
+		path       string
+	}{
+		{name: "standard streaming data source", path: "/all"},
+		{name: "polling data system", dataSystem: "polling", path: "/sdk/latest-all"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearHTTPEnv(t)
+			t.Setenv("APP_INSTANCE_ID", "app-1")
+			t.Setenv("APP_DATA_SYSTEM", tt.dataSystem)
+
+			type request struct {
+				path   string
+				header string
+			}
+			requests := make(chan request, 100)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case requests <- request{path: r.URL.Path, header: r.Header.Get(instanceHeader)}:
+				default:
+				}
+				w.WriteHeader(http.StatusUnauthorized)
+			}))
+			defer server.Close()
+
+			httpConfig, err := makeHTTPConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			dataSystem, err := makeDataSystem(server.URL, dataSourceTiming{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			conf := ldclient.Config{
+				HTTP:             httpConfig,
+				DataSystem:       dataSystem,
+				ServiceEndpoints: interfaces.ServiceEndpoints{Streaming: server.URL, Polling: server.URL, Events: server.URL},
+			}
+			client, _ := ldclient.MakeCustomClient("sdk-key", conf, time.Second)
+			defer client.Close()
+
+			// events such as diagnostics may arrive first, so wait for the data source's own request
+			timeout := time.After(5 * time.Second)
+			for {
+				select {
+				case req := <-requests:
+					if req.path != tt.path {
+						continue
+					}
+					if req.header != "app-1" {
+						t.Errorf("%s header on %s is %q, want %q", instanceHeader, req.path, req.header, "app-1")
+					}
+					return
+				case <-timeout:
+					t.Fatalf("the SDK made no request to %s", tt.path)
+				}
+			}
+		})
+	}
+}
@@ -1,8 +1,15 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/launchdarkly/go-sdk-common/v3/ldcontext"
@@ -11,56 +18,229 @@ import (
 )
 
 func main() {
+	// SIGINT/SIGTERM cancel ctx instead of killing the process, so the client is always closed
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx)
+	stop()
+	os.Exit(code)
+}
+
+// run evaluates the configured flags and returns the process exit code
+// it never calls os.Exit itself, so that the deferred client.Close flushes events and closes connections
+// long-running modes should return once ctx is cancelled by a shutdown signal
+func run(ctx context.Context) int {
 
 	// client could connect to dev-server or LaunchDarkly
-	client, err := makeLdClient()
+	client, err := makeLdClient(ctx)
+
+	// MakeClient returns a usable client along with an error when initialization times out
+	if client != nil {
+		defer client.Close()
+	}
 
 	if err != nil {
-		fmt.Println("Error creating client:", err)
-		os.Exit(1)
+		switch {
+		case errors.Is(err, context.Canceled):
+			fmt.Println("Shutting down: interrupted while creating client")
+		case errors.Is(err, ldclient.ErrInitializationTimeout):
+			fmt.Println("Error creating client: timed out waiting for initialization while the client was still connecting:", err)
+		case errors.Is(err, ldclient.ErrInitializationFailed):
+			fmt.Println("Error creating client: initialization failed and will not succeed on retry:",
+				initFailureReason(client, err))
+		default:
+			fmt.Println("Error creating client:", err)
+		}
+		return 1
 	}
 
-	// specify the flag key via an environment variable
-	flagKey := os.Getenv("APP_FLAG_KEY")
+	if client.IsOffline() {
+		fmt.Println("Running offline: flag evaluations will return their default values")
+	}
 
 	// Build context for flag evaluation
-	// NOTE: The dev-server does not serve targeting rules
-	context := ldcontext.NewBuilder("context-key-123abc").
-		Name("Sandy").
-		Build()
+	evalContext, err := makeContext()
+	if err != nil {
+		fmt.Println("Error building context:", err)
+		return 1
+	}
+
+	// APP_SERVE_ADDR runs an HTTP server that evaluates flags on request instead of evaluating once
+	if addr := os.Getenv("APP_SERVE_ADDR"); addr != "" {
+		if err := serve(ctx, addr, client, evalContext); err != nil {
+			fmt.Println("Error serving evaluations:", err)
+			return 1
+		}
+		return 0
+	}
+
+	// APP_EVAL_ALL=true dumps every flag instead of evaluating a single key
+	if os.Getenv("APP_EVAL_ALL") == "true" {
+		if err := printAllFlags(client, evalContext); err != nil {
+			fmt.Println("Error evaluating all flags:", err)
+			return 1
+		}
+		return 0
+	}
+
+	// specify the flag keys via an environment variable, as a single key or a comma-separated list
+	keys, err := flagKeys()
+	if err != nil {
+		fmt.Println("Error reading flag keys:", err)
+		return 1
+	}
+
+	// APP_WITH_REASON=true also prints the variation index and evaluation reason
+	if !evaluateFlags(client, keys, evalContext, os.Getenv("APP_WITH_REASON") == "true") {
+		return 1
+	}
+
+	// APP_WATCH=true keeps the client running and prints the flags again whenever they change
+	if os.Getenv("APP_WATCH") == "true" {
+		watchFlags(ctx, client, keys, evalContext)
+	}
+	return 0
+}
+
+// initFailureReason describes why the data source shut down during initialization
+// the status provider keeps the last error, so a rejected SDK key can be reported as such instead of generically
+func initFailureReason(client *ldclient.LDClient, err error) string {
+	lastError := client.GetDataSourceStatusProvider().GetStatus().LastError
+	switch {
+	case lastError.StatusCode == http.StatusUnauthorized || lastError.StatusCode == http.StatusForbidden:
+		return fmt.Sprintf("SDK key rejected (%d)", lastError.StatusCode)
+	case lastError.Kind != "":
+		return lastError.String()
+	default:
+		return err.Error()
+	}
+}
+
+// printAllFlags evaluates every flag for the context and prints the flag keys and values as JSON
+func printAllFlags(client *ldclient.LDClient, evalContext ldcontext.Context) error {
+	state := client.AllFlagsState(evalContext)
+	if !state.IsValid() {
+		if client.IsOffline() {
+			return errors.New("flag state is not available in offline mode")
+		}
+		return errors.New("client is not initialized")
+	}
 
-	result, err := client.BoolVariation(flagKey, context, false)
+	out, err := json.MarshalIndent(state.ToValuesMap(), "", "  ")
 	if err != nil {
-		fmt.Println("Error evaluating flag:", err)
-		os.Exit(1)
+		return err
 	}
 
-	fmt.Printf("Flag Key [%s] result: [%v]", flagKey, result)
+	fmt.Println(string(out))
+	return nil
 }
 
 // makeLdClient returns a LDClient
 // if LD_BASE_URI is set for the local dev server, then we configure the client to use the local dev server
-func makeLdClient() (*ldclient.LDClient, error) {
-	sdkKey := os.Getenv("LD_SDK_KEY")
-	if sdkKey == "" {
-		fmt.Println("LD_SDK_KEY environment variable not set")
-		os.Exit(1)
+// and first check that it responds, unless APP_SKIP_PREFLIGHT is true
+// if APP_OFFLINE is true, the client makes no network connections and evaluations return defaults
+// APP_DATA_SYSTEM selects the streaming, polling or default data system
+// APP_POLL_INTERVAL and APP_RECONNECT_DELAY override the polling interval and initial streaming reconnect delay
+// APP_CA_CERT and APP_TLS_INSECURE configure TLS for a dev server with a self-signed certificate
+// APP_INSTANCE_ID labels the SDK's requests to the dev server
+// APP_INIT_TIMEOUT sets how long to wait for initialization
+// APP_CONNECT_RETRIES and APP_CONNECT_BACKOFF retry a failed preflight or a timed out initialization,
+// stopping early if ctx is cancelled
+func makeLdClient(ctx context.Context) (*ldclient.LDClient, error) {
+	sdkKey, err := readSdkKey()
+	if err != nil {
+		return nil, err
 	}
 
-	var client *ldclient.LDClient
-	var err error
+	var conf ldclient.Config
 	baseUri := os.Getenv("LD_BASE_URI")
-	if baseUri == "" {
-		client, err = ldclient.MakeClient(sdkKey, 5*time.Second)
-	} else {
-		conf := ldclient.Config{
-			ServiceEndpoints: interfaces.ServiceEndpoints{
-				Streaming: baseUri,
-				Polling:   baseUri,
-				Events:    baseUri,
-			},
+	offline := os.Getenv("APP_OFFLINE") == "true"
+	checkDevServer := baseUri != "" && !offline && os.Getenv("APP_SKIP_PREFLIGHT") != "true"
+	if baseUri != "" {
+		conf.ServiceEndpoints = interfaces.ServiceEndpoints{
+			Streaming: baseUri,
+			Polling:   baseUri,
+			Events:    baseUri,
 		}
-		client, err = ldclient.MakeCustomClient(sdkKey, conf, 5*time.Second)
 	}
-	return client, err
+	conf.Offline = offline
+
+	timing, err := readDataSourceTiming()
+	if err != nil {
+		return nil, err
+	}
+	dataSystem, err := makeDataSystem(baseUri, timing)
+	if err != nil {
+		return nil, err
+	}
+	conf.DataSystem = dataSystem
+	if dataSystem == nil {
+		conf.DataSource = makeDataSource(timing)
+	}
+
+	httpConfig, err := makeHTTPConfig()
+	if err != nil {
+		return nil, err
+	}
+	conf.HTTP = httpConfig
+
+	timeout, err := initTimeout()
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := connectRetryPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	// the preflight is part of each attempt, so retries also wait for a dev server that hasn't started yet
+	return connectWithRetry(ctx, policy, func() (*ldclient.LDClient, error) {
+		if checkDevServer {
+			if err := preflight(ctx, baseUri); err != nil {
+				return nil, err
+			}
+		}
+		return ldclient.MakeCustomClient(sdkKey, conf, timeout)
+	})
+}
+
+// readSdkKey returns the SDK key from the file named by LD_SDK_KEY_FILE, following the docker secrets convention,
+// or from LD_SDK_KEY. The file takes precedence when both are set.
+func readSdkKey() (string, error) {
+	keyFile := os.Getenv("LD_SDK_KEY_FILE")
+	if keyFile == "" {
+		sdkKey := os.Getenv("LD_SDK_KEY")
+		if sdkKey == "" {
+			return "", errors.New("LD_SDK_KEY environment variable not set")
+		}
+		return sdkKey, nil
+	}
+
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return "", fmt.Errorf("reading LD_SDK_KEY_FILE: %w", err)
+	}
+	sdkKey := strings.TrimSpace(string(data))
+	if sdkKey == "" {
+		return "", fmt.Errorf("LD_SDK_KEY_FILE %s is empty", keyFile)
+	}
+	return sdkKey, nil
+}
+
+// initTimeout returns how long to wait for the client to initialize
+// APP_INIT_TIMEOUT is a Go duration string such as 30s, defaulting to 5s
+func initTimeout() (time.Duration, error) {
+	value := os.Getenv("APP_INIT_TIMEOUT")
+	if value == "" {
+		return 5 * time.Second, nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid APP_INIT_TIMEOUT: %w", err)
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("invalid APP_INIT_TIMEOUT %q: must be greater than zero", value)
+	}
+	return timeout, nil
 }
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ldclient "github.com/launchdarkly/go-server-sdk/v7"
+	"github.com/launchdarkly/go-server-sdk/v7/interfaces"
+)
+
+func TestInitFailureReasonRejectedSDKKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	conf := ldclient.Config{
+		ServiceEndpoints: interfaces.ServiceEndpoints{Streaming: server.URL, Polling: server.URL, Events: server.URL},
+	}
+	client, err := ldclient.MakeCustomClient("bad-sdk-key", conf, 5*time.Second)
+	defer client.Close()
+
+	if !errors.Is(err, ldclient.ErrInitializationFailed) {
+		t.Fatalf("expected ErrInitializationFailed, got %v", err)
+	}
+	if got, want := initFailureReason(client, err), "SDK key rejected (401)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const preflightTimeout = 2 * time.Second
+
+// notRespondingError is returned by preflight when nothing answers at the dev server's uri
+// connectWithRetry treats it like an initialization timeout, since the dev server may still be starting
+type notRespondingError struct {
+	baseUri string
+	err     error
+}
+
+func (e *notRespondingError) Error() string {
+	return fmt.Sprintf("dev server at %s is not responding: %v", e.baseUri, e.err)
+}
+
+func (e *notRespondingError) Unwrap() error {
+	return e.err
+}
+
+// preflight checks that something is answering HTTP at baseUri before the client spends its init timeout
+// any response counts as reachable, whatever its status code. A certificate the default roots don't trust
+// also counts, since the server did answer; APP_CA_CERT and APP_TLS_INSECURE decide whether the SDK accepts it.
+func preflight(ctx context.Context, baseUri string) error {
+	ctx, cancel := context.WithTimeout(ctx, preflightTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseUri, nil)
+	if err != nil {
+		return fmt.Errorf("invalid LD_BASE_URI %q: %w", baseUri, err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		var certErr *tls.CertificateVerificationError
+		if errors.As(err, &certErr) {
+			return nil
+		}
+		return &notRespondingError{baseUri: baseUri, err: err}
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ldclient "github.com/launchdarkly/go-server-sdk/v7"
+)
+
+func TestPreflight(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer tlsServer.Close()
+
+	closed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	closedURL := closed.URL
+	closed.Close()
+
+	tests := []struct {
+		name              string
+		baseUri           string
+		wantErr           bool
+		wantNotResponding bool
+	}{
+		{name: "any status code", baseUri: server.URL},
+		{name: "untrusted certificate", baseUri: tlsServer.URL},
+		{name: "closed port", baseUri: closedURL, wantErr: true, wantNotResponding: true},
+		{name: "invalid uri", baseUri: "http://[::1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := preflight(context.Background(), tt.baseUri)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			var notResponding *notRespondingError
+			if got := errors.As(err, &notResponding); got != tt.wantNotResponding {
+				t.Errorf("errors.As(notRespondingError) = %v for %v", got, err)
+			}
+		})
+	}
+}
+
+func TestConnectWithRetryRetriesPreflight(t *testing.T) {
+	attempts := 0
+	makeClient := func() (*ldclient.LDClient, error) {
+		attempts++
+		if attempts < 3 {
+			return nil, &notRespondingError{baseUri: "http://ld-dev-server:8765", err: errors.New("connection refused")}
+		}
+		return ldclient.MakeCustomClient("sdk-key", ldclient.Config{Offline: true}, 0)
+	}
+
+	client, err := connectWithRetry(context.Background(), retryPolicy{retries: 3, backoff: time.Millisecond}, makeClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer client.Close()
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+}
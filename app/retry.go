@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	ldclient "github.com/launchdarkly/go-server-sdk/v7"
+)
+
+// maxConnectBackoff caps the doubling wait between retries, so a large APP_CONNECT_RETRIES can't overflow it
+const maxConnectBackoff = 30 * time.Second
+
+// retryPolicy controls how often client creation is retried when initialization times out
+type retryPolicy struct {
+	retries int
+	backoff time.Duration
+}
+
+// connectRetryPolicy reads APP_CONNECT_RETRIES (default 0, a single attempt) and
+// APP_CONNECT_BACKOFF (a Go duration string, default 1s), the wait before the first retry
+func connectRetryPolicy() (retryPolicy, error) {
+	policy := retryPolicy{backoff: time.Second}
+
+	if value := os.Getenv("APP_CONNECT_RETRIES"); value != "" {
+		retries, err := strconv.Atoi(value)
+		if err != nil || retries < 0 {
+			return retryPolicy{}, fmt.Errorf("invalid APP_CONNECT_RETRIES %q: must be a non-negative integer", value)
+		}
+		policy.retries = retries
+	}
+
+	if value := os.Getenv("APP_CONNECT_BACKOFF"); value != "" {
+		backoff, err := time.ParseDuration(value)
+		if err != nil {
+			return retryPolicy{}, fmt.Errorf("invalid APP_CONNECT_BACKOFF: %w", err)
+		}
+		if backoff <= 0 {
+			return retryPolicy{}, fmt.Errorf("invalid APP_CONNECT_BACKOFF %q: must be greater than zero", value)
+		}
+		policy.backoff = backoff
+	}
+
+	return policy, nil
+}
+
+// connectWithRetry calls makeClient until it succeeds, doubling the wait between attempts
+// only an initialization timeout or an unresponsive dev server is retried, since the dev server may just not be
+// up yet. Any other error, such as a rejected SDK key, is returned straight away because another attempt would
+// fail the same way. Cancelling ctx stops both an attempt in progress and the wait between attempts.
+func connectWithRetry(ctx context.Context, policy retryPolicy,
+	makeClient func() (*ldclient.LDClient, error)) (*ldclient.LDClient, error) {
+	client, err := makeClientUntil(ctx, makeClient)
+	backoff := policy.backoff
+	for attempt := 1; attempt <= policy.retries && isRetryable(err); attempt++ {
+		// the timed out client would keep connecting in the background, so close it before starting another
+		if client != nil {
+			client.Close()
+		}
+
+		reason := "Client initialization timed out"
+		var notResponding *notRespondingError
+		if errors.As(err, &notResponding) {
+			reason = notResponding.Error()
+		}
+		fmt.Printf("%s, retrying in %s (retry %d of %d)\n", reason, backoff, attempt, policy.retries)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = nextBackoff(backoff)
+
+		client, err = makeClientUntil(ctx, makeClient)
+	}
+	return client, err
+}
+
+// isRetryable reports whether another connection attempt could succeed where err failed
+func isRetryable(err error) bool {
+	var notResponding *notRespondingError
+	return errors.Is(err, ldclient.ErrInitializationTimeout) || errors.As(err, &notResponding)
+}
+
+// nextBackoff doubles backoff up to maxConnectBackoff, leaving a longer APP_CONNECT_BACKOFF as it is
+func nextBackoff(backoff time.Duration) time.Duration {
+	if backoff >= maxConnectBackoff {
+		return backoff
+	}
+	return min(backoff*2, maxConnectBackoff)
+}
+
+// makeClientUntil calls makeClient, but returns ctx.Err() as soon as ctx is cancelled
+// MakeCustomClient blocks for up to the init timeout and can't be interrupted, so without this a shutdown signal
+// would only take effect once initialization gave up. A client that is created after cancellation is closed.
+func makeClientUntil(ctx context.Context,
+	makeClient func() (*ldclient.LDClient, error)) (*ldclient.LDClient, error) {
+	type result struct {
+		client *ldclient.LDClient
+		err    error
+	}
+	results := make(chan result, 1)
+	go func() {
+		client, err := makeClient()
+		results <- result{client, err}
+	}()
+
+	select {
+	case r := <-results:
+		return r.client, r.err
+	case <-ctx.Done():
+		go func() {
+			if r := <-results; r.client != nil {
+				r.client.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ldclient "github.com/launchdarkly/go-server-sdk/v7"
+)
+
+func TestConnectWithRetryStopsWhenCancelledDuringAttempt(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	unblock := make(chan struct{})
+	defer close(unblock)
+
+	// stands in for MakeCustomClient waiting out a long init timeout
+	makeClient := func() (*ldclient.LDClient, error) {
+		<-unblock
+		return nil, ldclient.ErrInitializationTimeout
+	}
+
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	client, err := connectWithRetry(ctx, retryPolicy{retries: 3, backoff: time.Second}, makeClient)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if client != nil {
+		t.Error("expected no client after cancellation")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("took %s to return after cancellation", elapsed)
+	}
+}
+
+func TestConnectWithRetryStopsWhenCancelledDuringBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var attempts atomic.Int32
+	makeClient := func() (*ldclient.LDClient, error) {
+		attempts.Add(1)
+		cancel()
+		// a timed out client is still returned, and connectWithRetry closes it before waiting
+		client, _ := ldclient.MakeCustomClient("sdk-key", ldclient.Config{Offline: true}, 0)
+		return client, ldclient.ErrInitializationTimeout
+	}
+
+	_, err := connectWithRetry(ctx, retryPolicy{retries: 3, backoff: time.Hour}, makeClient)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if n := attempts.Load(); n != 1 {
+		t.Errorf("expected 1 attempt, got %d", n)
+	}
+}
+
+func TestNextBackoff(t *testing.T) {
+	tests := []struct {
+		backoff, want time.Duration
+	}{
+		{time.Second, 2 * time.Second},
+		{20 * time.Second, maxConnectBackoff},
+		{maxConnectBackoff, maxConnectBackoff},
+		{time.Minute, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := nextBackoff(tt.backoff); got != tt.want {
+			t.Errorf("nextBackoff(%s) = %s, want %s", tt.backoff, got, tt.want)
+		}
+	}
+
+	// doubling many times must stay at the cap rather than overflowing
+	backoff := time.Second
+	for i := 0; i < 100; i++ {
+		backoff = nextBackoff(backoff)
+	}
+	if backoff != maxConnectBackoff {
+		t.Errorf("after 100 retries backoff is %s, want %s", backoff, maxConnectBackoff)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/launchdarkly/go-sdk-common/v3/ldcontext"
+	"github.com/launchdarkly/go-sdk-common/v3/ldreason"
+	"github.com/launchdarkly/go-sdk-common/v3/ldvalue"
+	ldclient "github.com/launchdarkly/go-server-sdk/v7"
+)
+
+const (
+	shutdownTimeout   = 5 * time.Second
+	readHeaderTimeout = 5 * time.Second
+
+	// maxContextBodySize limits the context JSON accepted in a request body
+	maxContextBodySize = 64 << 10
+)
+
+// evalResponse is the JSON body returned by /eval
+type evalResponse struct {
+	Value  ldvalue.Value             `json:"value"`
+	Reason ldreason.EvaluationReason `json:"reason"`
+	Error  string                    `json:"error,omitempty"`
+}
+
+// serve runs an HTTP server on addr that evaluates flags with client, until ctx is cancelled
+// GET /eval?flag=<key> evaluates the flag for defaultContext. A context can instead be given in the request body
+// as LaunchDarkly context JSON, or with the key and optional kind query parameters.
+func serve(ctx context.Context, addr string, client *ldclient.LDClient, defaultContext ldcontext.Context) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/eval", func(w http.ResponseWriter, r *http.Request) {
+		handleEval(w, r, client, defaultContext)
+	})
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: readHeaderTimeout}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+	fmt.Printf("Serving flag evaluations on %s, press Ctrl+C to exit\n", addr)
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	// let in-flight evaluations finish before the client is closed
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// handleEval evaluates the flag named by the flag query parameter and writes an evalResponse
+func handleEval(w http.ResponseWriter, r *http.Request, client *ldclient.LDClient, defaultContext ldcontext.Context) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flagKey := r.URL.Query().Get("flag")
+	if flagKey == "" {
+		http.Error(w, "missing flag query parameter", http.StatusBadRequest)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxContextBodySize)
+	evalContext, err := requestContext(r, defaultContext)
+	if err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	value, detail, err := client.JSONVariationDetail(flagKey, evalContext, ldvalue.Null())
+	resp := evalResponse{Value: value, Reason: detail.Reason}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		fmt.Println("Error writing evaluation response:", err)
+	}
+}
+
+// requestContext returns the evaluation context for a request
+// a JSON body takes precedence, then the key and kind query parameters, then defaultContext
+func requestContext(r *http.Request, defaultContext ldcontext.Context) (ldcontext.Context, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return ldcontext.Context{}, fmt.Errorf("reading request body: %w", err)
+	}
+	if len(body) > 0 {
+		return parseContext(body, "request body")
+	}
+
+	query := r.URL.Query()
+	key := query.Get("key")
+	if key == "" {
+		if query.Get("kind") != "" {
+			return ldcontext.Context{}, errors.New("kind query parameter requires a key")
+		}
+		return defaultContext, nil
+	}
+
+	c := ldcontext.NewWithKind(ldcontext.Kind(query.Get("kind")), key)
+	if err := c.Err(); err != nil {
+		return ldcontext.Context{}, fmt.Errorf("invalid context: %w", err)
+	}
+	return c, nil
+}
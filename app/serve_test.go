@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/launchdarkly/go-sdk-common/v3/ldcontext"
+	ldclient "github.com/launchdarkly/go-server-sdk/v7"
+)
+
+func TestHandleEval(t *testing.T) {
+	client, err := ldclient.MakeCustomClient("sdk-key", ldclient.Config{Offline: true}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleEval(w, r, client, ldcontext.New("default-key"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		method     string
+		query      string
+		body       string
+		wantStatus int
+	}{
+		{name: "default context", method: http.MethodGet, query: "flag=my-flag", wantStatus: http.StatusOK},
+		{name: "query context", method: http.MethodGet, query: "flag=my-flag&key=abc&kind=org", wantStatus: http.StatusOK},
+		{name: "body context", method: http.MethodGet, query: "flag=my-flag",
+			body: `{"kind": "user", "key": "abc"}`, wantStatus: http.StatusOK},
+		{name: "missing flag", method: http.MethodGet, wantStatus: http.StatusBadRequest},
+		{name: "kind without key", method: http.MethodGet, query: "flag=my-flag&kind=org",
+			wantStatus: http.StatusBadRequest},
+		{name: "malformed body", method: http.MethodGet, query: "flag=my-flag", body: "{",
+			wantStatus: http.StatusBadRequest},
+		{name: "body too large", method: http.MethodGet, query: "flag=my-flag",
+			body: strings.Repeat(" ", maxContextBodySize+1), wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "POST", method: http.MethodPost, query: "flag=my-flag", wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, server.URL+"/eval?"+tt.query, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var body map[string]json.RawMessage
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			// an offline client returns the null default along with a reason
+			if string(body["value"]) != "null" {
+				t.Errorf("value is %s, want null", body["value"])
+			}
+			if _, ok := body["reason"]; !ok {
+				t.Error("response has no reason")
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/launchdarkly/go-sdk-common/v3/ldcontext"
+	"github.com/launchdarkly/go-sdk-common/v3/ldvalue"
+	ldclient "github.com/launchdarkly/go-server-sdk/v7"
+	"github.com/launchdarkly/go-server-sdk/v7/interfaces"
+)
+
+// watchFlags re-prints a flag's value every time it changes, until ctx is cancelled
+// data source status transitions are printed too, so a dropped dev-server connection is visible
+func watchFlags(ctx context.Context, client *ldclient.LDClient, flagKeys []string, evalContext ldcontext.Context) {
+	tracker := client.GetFlagTracker()
+
+	// the tracker gives each flag its own channel, so forward them all into one
+	changes := make(chan interfaces.FlagValueChangeEvent)
+	for _, flagKey := range flagKeys {
+		listener := tracker.AddFlagValueChangeListener(flagKey, evalContext, ldvalue.Bool(false))
+		defer tracker.RemoveFlagValueChangeListener(listener)
+		go forwardChanges(ctx, listener, changes)
+	}
+
+	statusProvider := client.GetDataSourceStatusProvider()
+	statuses := statusProvider.AddStatusListener()
+	defer statusProvider.RemoveStatusListener(statuses)
+
+	fmt.Printf("Watching Flag Keys [%s] for changes, press Ctrl+C to exit\n", strings.Join(flagKeys, ","))
+
+	lastState := statusProvider.GetStatus().State
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-changes:
+			fmt.Printf("Flag Key [%s] result: [%v]\n", event.Key, event.NewValue)
+		case status := <-statuses:
+			if status.State == lastState {
+				continue
+			}
+			fmt.Printf("Data source status changed: [%s] -> [%s]", lastState, status.State)
+			if status.LastError.Kind != "" {
+				fmt.Printf(" last error: [%s]", status.LastError)
+			}
+			fmt.Println()
+			lastState = status.State
+		}
+	}
+}
+
+// forwardChanges copies events from a single flag's listener to out until ctx is cancelled
+func forwardChanges(ctx context.Context, listener <-chan interfaces.FlagValueChangeEvent,
+	out chan<- interfaces.FlagValueChangeEvent) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-listener:
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}